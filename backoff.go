@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// retryConfig is the resolved, duration-typed form of Config.Retry used by
+// the backoff generator and ForceReannounce.
+type retryConfig struct {
+	baseInterval       time.Duration
+	maxInterval        time.Duration
+	multiplier         float64
+	jitter             bool
+	honorTrackerHint   bool
+	fatalTrackerErrors []string
+}
+
+// retryConfigFromConfig converts the YAML-facing Config.Retry section into a
+// retryConfig. loadConfig is expected to have already applied defaults.
+func retryConfigFromConfig(config *Config) retryConfig {
+	return retryConfig{
+		baseInterval:       time.Duration(config.Retry.BaseInterval) * time.Second,
+		maxInterval:        time.Duration(config.Retry.MaxInterval) * time.Second,
+		multiplier:         config.Retry.Multiplier,
+		jitter:             config.Retry.Jitter,
+		honorTrackerHint:   config.Retry.HonorTrackerHint,
+		fatalTrackerErrors: config.Retry.FatalTrackerErrors,
+	}
+}
+
+// backoff produces the wait duration between successive ForceReannounce
+// attempts: plain exponential backoff by default, or decorrelated jitter
+// (https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/)
+// when cfg.jitter is set.
+type backoff struct {
+	cfg  retryConfig
+	prev time.Duration
+}
+
+func newBackoff(cfg retryConfig) *backoff {
+	return &backoff{cfg: cfg}
+}
+
+// next returns the wait before the next attempt, and records it so
+// subsequent calls grow from it.
+func (b *backoff) next() time.Duration {
+	if b.prev == 0 {
+		b.prev = b.cfg.baseInterval
+		return b.prev
+	}
+
+	var d time.Duration
+	if b.cfg.jitter {
+		upper := b.prev * 3
+		if upper <= b.cfg.baseInterval {
+			d = b.cfg.baseInterval
+		} else {
+			d = b.cfg.baseInterval + time.Duration(rand.Int63n(int64(upper-b.cfg.baseInterval)))
+		}
+	} else {
+		d = time.Duration(float64(b.prev) * b.cfg.multiplier)
+	}
+
+	if d > b.cfg.maxInterval {
+		d = b.cfg.maxInterval
+	}
+	b.prev = d
+	return d
+}
+
+// isFatalTrackerStatus reports whether trackerStatus matches one of the
+// configured fatal tracker error substrings, e.g. "Error: not registered" or
+// "unauthorized", which indicate retrying is pointless.
+func isFatalTrackerStatus(trackerStatus string, fatalTrackerErrors []string) bool {
+	for _, fatal := range fatalTrackerErrors {
+		if fatal != "" && strings.Contains(strings.ToLower(trackerStatus), strings.ToLower(fatal)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffExponentialGrowsAndCaps(t *testing.T) {
+	b := newBackoff(retryConfig{
+		baseInterval: time.Second,
+		maxInterval:  5 * time.Second,
+		multiplier:   2,
+	})
+
+	got := []time.Duration{b.next(), b.next(), b.next(), b.next()}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("attempt %d: expected wait %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestBackoffJitterStaysWithinBounds(t *testing.T) {
+	b := newBackoff(retryConfig{
+		baseInterval: time.Second,
+		maxInterval:  10 * time.Second,
+		multiplier:   2,
+		jitter:       true,
+	})
+
+	for i := 0; i < 20; i++ {
+		d := b.next()
+		if d < 1*time.Second || d > 10*time.Second {
+			t.Fatalf("jittered wait %v out of bounds [1s, 10s]", d)
+		}
+	}
+}
+
+func TestIsFatalTrackerStatus(t *testing.T) {
+	fatal := []string{"Error: not registered", "unauthorized"}
+
+	cases := map[string]bool{
+		"Announce OK":               false,
+		"Error: not registered":     true,
+		"error: not registered (a)": true,
+		"401 Unauthorized":          true,
+		"":                          false,
+	}
+
+	for status, want := range cases {
+		if got := isFatalTrackerStatus(status, fatal); got != want {
+			t.Errorf("isFatalTrackerStatus(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
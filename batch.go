@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stringSliceFlag implements flag.Value for a flag that can be repeated on
+// the command line, e.g. -torrent-id a -torrent-id b.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// collectBatchTorrentIDs merges the repeatable -torrent-id flag with the IDs
+// listed in -torrent-id-file (one per line, blank lines and #-comments
+// ignored).
+func collectBatchTorrentIDs(flagIDs stringSliceFlag, idFile string) ([]string, error) {
+	ids := append([]string{}, flagIDs...)
+
+	if idFile == "" {
+		return ids, nil
+	}
+
+	file, err := os.Open(idFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open torrent ID file %s: %w", idFile, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read torrent ID file %s: %w", idFile, err)
+	}
+
+	return ids, nil
+}
+
+// runBatch reannounces every torrent in torrentIDs and exits with a status
+// reflecting whether all of them succeeded.
+func runBatch(config *Config, logger *Logger, torrentIDs []string, workers int) {
+	logger.Info("Received batch reannounce request for %d torrents", len(torrentIDs))
+
+	settings := delugeSettings(config)
+
+	client := NewDelugeClient(settings, logger)
+	client.SetRateLimiter(newTrackerLimiter(*config))
+	client.SetRetryConfig(retryConfigFromConfig(config))
+	if err := client.Connect(); err != nil {
+		logger.Info("Error: %v", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	timeout := time.Duration(config.Retry.Timeout) * time.Second
+	results := client.ForceReannounceBatch(torrentIDs, timeout, workers)
+
+	failed := 0
+	for _, torrentID := range torrentIDs {
+		success := results[torrentID]
+		logger.Info("Batch reannounce result for torrent %s: success=%v", torrentID, success)
+		if !success {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		logger.Error("Batch reannounce finished with %d/%d torrents failed", failed, len(torrentIDs))
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// ForceReannounceBatch reannounces many torrents at once. IDs are grouped by
+// their current tracker host (resolved with an upfront TorrentsStatus call,
+// and refreshed on every poll tick) so both the initial attempt and every
+// retry wait on the real per-tracker rate limiter bucket instead of a shared
+// "unknown" one. Every tracker's group, on the initial attempt and on each
+// retry alike, is chunked across a worker pool so large batches aren't held
+// up by go-libdeluge's argument limits on a single ForceReannounce call.
+// Follow-up status checks use one batched TorrentsStatus call per poll tick
+// instead of one TorrentStatus RPC per torrent per tick, and each tick also
+// re-issues ForceReannounce for whatever is still pending so a torrent whose
+// earlier RPC errored gets retried instead of being polled uselessly until it
+// times out. It returns a per-torrent success map once every torrent has
+// either succeeded, hit a fatal tracker error, or the shared timeout elapsed.
+func (d *DelugeClient) ForceReannounceBatch(torrentIDs []string, timeout time.Duration, workers int) map[string]bool {
+	results := make(map[string]bool, len(torrentIDs))
+	if len(torrentIDs) == 0 {
+		return results
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	deadline := time.Now().Add(timeout)
+	trackers := make(map[string]string, len(torrentIDs))
+
+	// Best-effort: learn each torrent's current tracker before the first
+	// attempt, so it's rate-limited against its real bucket rather than
+	// "unknown".
+	if statuses, err := d.torrentsStatus(torrentIDs); err != nil {
+		log.Printf("Error looking up initial tracker hosts for batch: %v", err)
+	} else {
+		for id, status := range statuses {
+			if status.TrackerHost != "" {
+				trackers[id] = status.TrackerHost
+			}
+		}
+	}
+
+	pending := make(map[string]bool, len(torrentIDs))
+	for _, id := range torrentIDs {
+		pending[id] = true
+	}
+
+	d.reannounceGroupedByTracker(torrentIDs, trackers, workers, deadline)
+
+	// Wait a bit for the torrents to start updating their status.
+	time.Sleep(2 * time.Second)
+
+	b := newBackoff(d.retry)
+	for len(pending) > 0 && time.Now().Before(deadline) {
+		d.pollBatch(pending, results, trackers)
+		if len(pending) == 0 {
+			break
+		}
+
+		wait := b.next()
+		if remaining := time.Until(deadline); wait > remaining {
+			wait = remaining
+		}
+		time.Sleep(wait)
+
+		ids := make([]string, 0, len(pending))
+		for id := range pending {
+			ids = append(ids, id)
+		}
+		d.reannounceGroupedByTracker(ids, trackers, workers, deadline)
+	}
+
+	for id := range pending {
+		log.Printf("Timeout reached waiting for torrent %s to reannounce", id)
+		results[id] = false
+	}
+
+	return results
+}
+
+// reannounceGroupedByTracker groups ids by their best-known tracker host and
+// issues one rate-limited ForceReannounce RPC per chunk, chunking each
+// tracker's group across a worker pool of the given size the same way on
+// every call, so a retry re-issue is bound by the same per-RPC argument
+// count as the initial fan-out. It blocks until every chunk has either been
+// sent or given up waiting on the rate limiter.
+func (d *DelugeClient) reannounceGroupedByTracker(ids []string, trackers map[string]string, workers int, deadline time.Time) {
+	var wg sync.WaitGroup
+	for tracker, group := range trackerGroups(ids, trackers) {
+		for _, chunk := range chunkStrings(group, workers) {
+			wg.Add(1)
+			go func(tracker string, chunk []string) {
+				defer wg.Done()
+
+				waitCtx, cancel := context.WithDeadline(context.Background(), deadline)
+				err := d.rateLimiter.wait(waitCtx, tracker)
+				cancel()
+				if err != nil {
+					log.Printf("Rate limit wait for tracker %s batch chunk would exceed the remaining timeout, giving up: %v", tracker, err)
+					return
+				}
+				if err := d.forceReannounce(chunk); err != nil {
+					log.Printf("Error force reannouncing batch chunk %v for tracker %s: %v", chunk, tracker, err)
+				}
+			}(tracker, chunk)
+		}
+	}
+	wg.Wait()
+}
+
+// trackerGroups buckets ids by their best-known tracker host, falling back
+// to "unknown" for any id whose tracker hasn't been resolved yet.
+func trackerGroups(ids []string, trackers map[string]string) map[string][]string {
+	groups := make(map[string][]string)
+	for _, id := range ids {
+		tracker := trackers[id]
+		if tracker == "" {
+			tracker = "unknown"
+		}
+		groups[tracker] = append(groups[tracker], id)
+	}
+	return groups
+}
+
+// chunkStrings splits ids into at most n roughly-equal, non-empty chunks.
+func chunkStrings(ids []string, n int) [][]string {
+	if n > len(ids) {
+		n = len(ids)
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	chunks := make([][]string, 0, n)
+	size := (len(ids) + n - 1) / n
+	for i := 0; i < len(ids); i += size {
+		end := i + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[i:end])
+	}
+	return chunks
+}
+
+// pollBatch issues one TorrentsStatus RPC for every still-pending torrent,
+// records each torrent's current tracker host into trackers so subsequent
+// ForceReannounce retries rate-limit against the right bucket, and moves the
+// torrents that succeeded or hit a fatal tracker error from pending into
+// results.
+func (d *DelugeClient) pollBatch(pending map[string]bool, results map[string]bool, trackers map[string]string) {
+	ids := make([]string, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+
+	statuses, err := d.torrentsStatus(ids)
+	if err != nil {
+		log.Printf("Error polling batch torrent status: %v", err)
+		return
+	}
+
+	for id, status := range statuses {
+		tracker := status.TrackerHost
+		if tracker == "" {
+			tracker = "unknown"
+		}
+		trackers[id] = tracker
+		d.metrics.SetTrackerStatus(tracker, status.TrackerStatus)
+
+		switch {
+		case (status.State == "Downloading" || status.State == "Seeding") && status.TrackerStatus == "Announce OK":
+			log.Printf("Successfully reannounced torrent %s (State: %s, Tracker: %s)", id, status.State, status.TrackerStatus)
+			d.metrics.RecordSuccess(tracker)
+			results[id] = true
+			delete(pending, id)
+		case isFatalTrackerStatus(status.TrackerStatus, d.retry.fatalTrackerErrors):
+			log.Printf("Tracker status %q for torrent %s looks fatal, giving up", status.TrackerStatus, id)
+			results[id] = false
+			delete(pending, id)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCollectBatchTorrentIDsMergesFlagAndFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	idFile := filepath.Join(tmpDir, "ids.txt")
+	content := "torrent-2\n\n# a comment\ntorrent-3\n"
+	if err := os.WriteFile(idFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write id file: %v", err)
+	}
+
+	ids, err := collectBatchTorrentIDs(stringSliceFlag{"torrent-1"}, idFile)
+	if err != nil {
+		t.Fatalf("collectBatchTorrentIDs returned an error: %v", err)
+	}
+
+	want := []string{"torrent-1", "torrent-2", "torrent-3"}
+	if len(ids) != len(want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	for i, id := range want {
+		if ids[i] != id {
+			t.Errorf("expected ids[%d] = %q, got %q", i, id, ids[i])
+		}
+	}
+}
+
+func TestForceReannounceBatchEmptyInput(t *testing.T) {
+	results := (&DelugeClient{}).ForceReannounceBatch(nil, 0, 4)
+	if len(results) != 0 {
+		t.Errorf("expected an empty result map for no torrent IDs, got %v", results)
+	}
+}
+
+func TestTrackerGroupsFallsBackToUnknown(t *testing.T) {
+	trackers := map[string]string{"a": "tracker1.example.com", "b": "tracker1.example.com"}
+	groups := trackerGroups([]string{"a", "b", "c"}, trackers)
+
+	if got := groups["tracker1.example.com"]; len(got) != 2 {
+		t.Errorf("expected a and b grouped under tracker1.example.com, got %v", got)
+	}
+	if got := groups["unknown"]; len(got) != 1 || got[0] != "c" {
+		t.Errorf("expected c with no known tracker to fall back to the unknown bucket, got %v", got)
+	}
+}
+
+// TestBatchPerTrackerOverrideGatesKnownTracker guards against the grouping
+// and the rate limiter bucket drifting apart: a batch torrent whose tracker
+// is known must wait on that tracker's own override, not the shared
+// "unknown" bucket the rest of the batch falls back to.
+func TestBatchPerTrackerOverrideGatesKnownTracker(t *testing.T) {
+	var config Config
+	config.RateLimit.Enabled = true
+	config.RateLimit.ReqsPerMinute = 6000 // effectively unlimited default bucket
+	config.RateLimit.Burst = 1
+	config.RateLimit.PerTracker = map[string]float64{"slow.example.com": 6} // 1 per 10s
+
+	lim := newTrackerLimiter(config)
+
+	trackers := map[string]string{"torrent-a": "slow.example.com"}
+	groups := trackerGroups([]string{"torrent-a", "torrent-b"}, trackers)
+
+	slowGroup, ok := groups["slow.example.com"]
+	if !ok || len(slowGroup) != 1 || slowGroup[0] != "torrent-a" {
+		t.Fatalf("expected torrent-a grouped under slow.example.com, got %v", groups)
+	}
+
+	// First attempt for the known-tracker torrent consumes its burst token.
+	if err := lim.wait(context.Background(), "slow.example.com"); err != nil {
+		t.Fatalf("expected first wait on the overridden tracker to succeed immediately, got: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := lim.wait(ctx, "slow.example.com"); err == nil {
+		t.Error("expected the per-tracker override to gate a second immediate attempt for the known tracker")
+	}
+
+	// The fallback bucket for the unresolved torrent must be unaffected.
+	if err := lim.wait(context.Background(), "unknown"); err != nil {
+		t.Errorf("expected the unknown bucket to stay ungated by the slow tracker's override, got: %v", err)
+	}
+}
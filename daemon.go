@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jobState represents where a reannounce job is in its lifecycle.
+type jobState string
+
+const (
+	jobQueued    jobState = "queued"
+	jobRunning   jobState = "running"
+	jobSucceeded jobState = "succeeded"
+	jobFailed    jobState = "failed"
+)
+
+// reannounceJob is a single unit of work submitted to the daemon's worker
+// pool, mirroring the positional arguments the one-shot CLI takes.
+type reannounceJob struct {
+	TorrentID      string `json:"torrent_id"`
+	TorrentName    string `json:"torrent_name"`
+	DownloadFolder string `json:"download_folder"`
+}
+
+// jobStatus tracks the outcome of a reannounceJob for the /status endpoint.
+type jobStatus struct {
+	Job       reannounceJob `json:"job"`
+	State     jobState      `json:"state"`
+	Error     string        `json:"error,omitempty"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// Daemon runs a persistent Deluge connection behind a bounded worker pool,
+// so Deluge's execute plugin can curl a local HTTP endpoint instead of
+// spawning a new process (and a new Deluge connection) per torrent.
+type Daemon struct {
+	client *DelugeClient
+	config *Config
+	logger *Logger
+
+	jobs chan reannounceJob
+
+	mu       sync.RWMutex
+	statuses map[string]*jobStatus
+}
+
+// NewDaemon creates a Daemon around an already-constructed DelugeClient.
+func NewDaemon(client *DelugeClient, config *Config, logger *Logger) *Daemon {
+	return &Daemon{
+		client:   client,
+		config:   config,
+		logger:   logger,
+		jobs:     make(chan reannounceJob, config.Daemon.QueueSize),
+		statuses: make(map[string]*jobStatus),
+	}
+}
+
+// Start launches the worker pool. It returns immediately; workers run until
+// the jobs channel is closed.
+func (d *Daemon) Start(workers int) {
+	for i := 0; i < workers; i++ {
+		go d.worker()
+	}
+}
+
+// worker pulls jobs off the queue and runs them to completion one at a time.
+func (d *Daemon) worker() {
+	for job := range d.jobs {
+		d.runJob(job)
+	}
+}
+
+// Enqueue submits a job to the worker pool without blocking. It returns an
+// error if the queue is full so callers (the HTTP handler) can surface
+// backpressure to the client instead of buffering unboundedly.
+func (d *Daemon) Enqueue(job reannounceJob) error {
+	d.setStatus(job, jobQueued, nil)
+
+	select {
+	case d.jobs <- job:
+		return nil
+	default:
+		return fmt.Errorf("reannounce queue is full (%d pending)", len(d.jobs))
+	}
+}
+
+func (d *Daemon) runJob(job reannounceJob) {
+	d.setStatus(job, jobRunning, nil)
+
+	timeout := time.Duration(d.config.Retry.Timeout) * time.Second
+
+	d.logger.Info("Daemon: starting reannounce for torrent %s (%s)", job.TorrentName, job.TorrentID)
+
+	if d.client.ForceReannounce(job.TorrentID, timeout) {
+		d.setStatus(job, jobSucceeded, nil)
+		d.logger.Info("Daemon: reannounce succeeded for torrent %s (%s)", job.TorrentName, job.TorrentID)
+		return
+	}
+
+	err := fmt.Errorf("reannounce did not succeed within %s", timeout)
+	d.setStatus(job, jobFailed, err)
+	d.logger.Error("Daemon: reannounce failed for torrent %s (%s): %v", job.TorrentName, job.TorrentID, err)
+}
+
+func (d *Daemon) setStatus(job reannounceJob, state jobState, err error) {
+	st := &jobStatus{
+		Job:       job,
+		State:     state,
+		UpdatedAt: time.Now(),
+	}
+	if err != nil {
+		st.Error = err.Error()
+	}
+
+	d.mu.Lock()
+	d.statuses[job.TorrentID] = st
+	d.mu.Unlock()
+}
+
+// WriteStatus writes a human-readable snapshot of the daemon and its
+// in-flight jobs, in the same vein as the status writers found in
+// anacrolix/torrent's example clients.
+func (d *Daemon) WriteStatus(w io.Writer) {
+	fmt.Fprintf(w, "deluge-reannounce daemon\n")
+	fmt.Fprintf(w, "deluge connected: %v\n", d.client.Connected())
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	fmt.Fprintf(w, "queued jobs: %d/%d\n", len(d.jobs), cap(d.jobs))
+	fmt.Fprintf(w, "\ntorrent\t\tname\t\tstate\t\terror\n")
+	for id, st := range d.statuses {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", id, st.Job.TorrentName, st.State, st.Error)
+	}
+}
+
+// handleReannounce accepts {torrent_id, torrent_name, download_folder} and
+// enqueues a reannounce job, matching the arguments the one-shot CLI
+// otherwise takes positionally.
+func (d *Daemon) handleReannounce(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var job reannounceJob
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if job.TorrentID == "" {
+		http.Error(w, "torrent_id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := d.Enqueue(job); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"torrent_id": job.TorrentID,
+		"state":      string(jobQueued),
+	})
+}
+
+// batchReannounceRequest is the body for POST /reannounce/batch.
+type batchReannounceRequest struct {
+	TorrentIDs []string `json:"torrent_ids"`
+}
+
+// batchReannounceResult is one torrent's entry in a /reannounce/batch response.
+type batchReannounceResult struct {
+	TorrentID string `json:"torrent_id"`
+	Success   bool   `json:"success"`
+}
+
+// handleReannounceBatch accepts {torrent_ids: [...]} and reannounces all of
+// them concurrently via ForceReannounceBatch, blocking until every torrent
+// has succeeded, hit a fatal tracker error, or the retry timeout elapsed.
+func (d *Daemon) handleReannounceBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req batchReannounceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(req.TorrentIDs) == 0 {
+		http.Error(w, "torrent_ids must contain at least one torrent ID", http.StatusBadRequest)
+		return
+	}
+
+	timeout := time.Duration(d.config.Retry.Timeout) * time.Second
+	outcomes := d.client.ForceReannounceBatch(req.TorrentIDs, timeout, d.config.Daemon.Workers)
+
+	results := make([]batchReannounceResult, 0, len(req.TorrentIDs))
+	for _, torrentID := range req.TorrentIDs {
+		results = append(results, batchReannounceResult{TorrentID: torrentID, Success: outcomes[torrentID]})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// handleStatus writes the daemon's current status in the WriteStatus format.
+func (d *Daemon) handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	d.WriteStatus(w)
+}
+
+// runDaemon starts the daemon mode: it connects once to Deluge, starts the
+// worker pool, and serves the HTTP API until the process is killed.
+func runDaemon(config *Config, logger *Logger) {
+	settings := delugeSettings(config)
+
+	client := NewDelugeClient(settings, logger)
+	client.SetRateLimiter(newTrackerLimiter(*config))
+	client.SetRetryConfig(retryConfigFromConfig(config))
+	if config.Metrics.Enabled {
+		client.SetMetrics(NewMetrics())
+	}
+	if err := client.Connect(); err != nil {
+		log.Fatalf("failed to connect to Deluge daemon: %v", err)
+	}
+	defer client.Close()
+
+	daemon := NewDaemon(client, config, logger)
+	daemon.Start(config.Daemon.Workers)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/reannounce", daemon.handleReannounce)
+	mux.HandleFunc("/reannounce/batch", daemon.handleReannounceBatch)
+	mux.HandleFunc("/status", daemon.handleStatus)
+
+	if config.Metrics.Enabled {
+		mountMetrics(mux, config, client.metrics, logger)
+	}
+
+	logger.Info("Daemon listening on %s (workers: %d, queue size: %d)",
+		config.Daemon.Listen, config.Daemon.Workers, config.Daemon.QueueSize)
+
+	if err := http.ListenAndServe(config.Daemon.Listen, mux); err != nil {
+		log.Fatalf("daemon HTTP server stopped: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	deluge "github.com/gdm85/go-libdeluge"
+)
+
+func newTestDaemon(t *testing.T) *Daemon {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	logger, err := NewLogger(&Config{
+		Logging: struct {
+			File  string `yaml:"file"`
+			Level string `yaml:"level"`
+		}{
+			File:  filepath.Join(tmpDir, "test.log"),
+			Level: "DEBUG",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	config := &Config{}
+	config.Daemon.Workers = 1
+	config.Daemon.QueueSize = 1
+	config.Retry.Timeout = 1
+	config.Retry.Interval = 1
+
+	client := NewDelugeClient(deluge.Settings{Hostname: "localhost", Port: 58846}, logger)
+
+	return NewDaemon(client, config, logger)
+}
+
+func TestDaemonEnqueueRejectsWhenQueueFull(t *testing.T) {
+	d := newTestDaemon(t)
+
+	// Fill the single queue slot without starting any workers to drain it.
+	if err := d.Enqueue(reannounceJob{TorrentID: "torrent-1"}); err != nil {
+		t.Fatalf("expected first enqueue to succeed, got: %v", err)
+	}
+
+	if err := d.Enqueue(reannounceJob{TorrentID: "torrent-2"}); err == nil {
+		t.Fatal("expected second enqueue to fail once the queue is full")
+	}
+}
+
+func TestDaemonHandleReannounceValidatesRequest(t *testing.T) {
+	d := newTestDaemon(t)
+
+	req := httptest.NewRequest("POST", "/reannounce", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+
+	d.handleReannounce(w, req)
+
+	if w.Code != 400 {
+		t.Errorf("expected 400 for a request missing torrent_id, got %d", w.Code)
+	}
+}
+
+func TestDaemonWriteStatusIncludesQueuedJob(t *testing.T) {
+	d := newTestDaemon(t)
+
+	if err := d.Enqueue(reannounceJob{TorrentID: "torrent-1", TorrentName: "Example"}); err != nil {
+		t.Fatalf("failed to enqueue job: %v", err)
+	}
+
+	var buf bytes.Buffer
+	d.WriteStatus(&buf)
+
+	out := buf.String()
+	if !strings.Contains(out, "torrent-1") || !strings.Contains(out, "Example") {
+		t.Errorf("expected status output to mention the queued job, got:\n%s", out)
+	}
+}
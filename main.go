@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	deluge "github.com/gdm85/go-libdeluge"
@@ -27,8 +29,33 @@ type Config struct {
 	} `yaml:"logging"`
 	Retry struct {
 		Timeout  int `yaml:"timeout"`  // in seconds
-		Interval int `yaml:"interval"` // in seconds
+		Interval int `yaml:"interval"` // in seconds; legacy fixed interval, used as the base_interval default
+
+		BaseInterval       int      `yaml:"base_interval"` // in seconds
+		MaxInterval        int      `yaml:"max_interval"`  // in seconds
+		Multiplier         float64  `yaml:"multiplier"`
+		Jitter             bool     `yaml:"jitter"`               // decorrelated jitter instead of plain exponential backoff
+		HonorTrackerHint   bool     `yaml:"honor_tracker_hint"`   // wait at least status.NextAnnounce between attempts
+		FatalTrackerErrors []string `yaml:"fatal_tracker_errors"` // tracker status substrings that abort immediately
 	} `yaml:"retry"`
+	Daemon struct {
+		Listen    string `yaml:"listen"`     // address to listen on, e.g. ":8112"
+		Workers   int    `yaml:"workers"`    // number of concurrent reannounce workers
+		QueueSize int    `yaml:"queue_size"` // max pending jobs before /reannounce returns 503
+	} `yaml:"daemon"`
+	Metrics struct {
+		Enabled     bool   `yaml:"enabled"`
+		Listen      string `yaml:"listen"`       // separate listener; empty mounts on the daemon's listener
+		Path        string `yaml:"path"`         // default "/metrics"
+		AdminToken  string `yaml:"admin_token"`  // if set, required as "Authorization: Bearer <token>"
+		ProxyHeader string `yaml:"proxy_header"` // if set, its presence on a request skips the admin token check
+	} `yaml:"metrics"`
+	RateLimit struct {
+		Enabled       bool               `yaml:"enabled"`
+		ReqsPerMinute float64            `yaml:"reqs_per_minute"` // global default, per tracker
+		Burst         int                `yaml:"burst"`
+		PerTracker    map[string]float64 `yaml:"per_tracker"` // tracker host -> reqs/minute override
+	} `yaml:"rate_limit"`
 }
 
 // Logger handles application logging
@@ -113,57 +140,179 @@ func loadConfig(configFile string) (*Config, error) {
 	if config.Retry.Interval == 0 {
 		config.Retry.Interval = 7
 	}
+	if config.Retry.BaseInterval == 0 {
+		config.Retry.BaseInterval = config.Retry.Interval
+	}
+	if config.Retry.MaxInterval == 0 {
+		config.Retry.MaxInterval = 120
+	}
+	if config.Retry.Multiplier == 0 {
+		config.Retry.Multiplier = 2.0
+	}
+	if len(config.Retry.FatalTrackerErrors) == 0 {
+		config.Retry.FatalTrackerErrors = []string{"Error: not registered", "unauthorized"}
+	}
+	if config.Daemon.Listen == "" {
+		config.Daemon.Listen = ":8112"
+	}
+	if config.Daemon.Workers == 0 {
+		config.Daemon.Workers = 4
+	}
+	if config.Daemon.QueueSize == 0 {
+		config.Daemon.QueueSize = 100
+	}
+	if config.Metrics.Path == "" {
+		config.Metrics.Path = "/metrics"
+	}
+	if config.RateLimit.Enabled && config.RateLimit.ReqsPerMinute == 0 {
+		config.RateLimit.ReqsPerMinute = 20
+	}
+	if config.RateLimit.Burst == 0 {
+		config.RateLimit.Burst = 1
+	}
 
 	return &config, nil
 }
 
 // DelugeClient wraps the Deluge client with additional functionality
 type DelugeClient struct {
-	client *deluge.ClientV2
-	logger *Logger
+	settings deluge.Settings
+	logger   *Logger
+
+	// mu guards client and connected, and serializes RPC calls so the
+	// single underlying connection can be shared safely across the
+	// worker pool used in daemon mode.
+	mu        sync.Mutex
+	client    *deluge.ClientV2
+	connected bool
+
+	metrics     *Metrics
+	rateLimiter *trackerLimiter
+	retry       retryConfig
+}
+
+// SetMetrics attaches a Metrics collector that subsequent calls will report
+// to. Passing nil disables metrics recording.
+func (d *DelugeClient) SetMetrics(metrics *Metrics) {
+	d.metrics = metrics
+}
+
+// SetRateLimiter attaches a per-tracker rate limiter that ForceReannounce
+// will wait on before each attempt. Passing nil disables rate limiting.
+func (d *DelugeClient) SetRateLimiter(rl *trackerLimiter) {
+	d.rateLimiter = rl
+}
+
+// SetRetryConfig overrides the backoff/fatal-error behavior ForceReannounce
+// uses. NewDelugeClient seeds a sane default, so calling this is optional.
+func (d *DelugeClient) SetRetryConfig(retry retryConfig) {
+	d.retry = retry
+}
+
+// defaultRetryConfig mirrors loadConfig's defaults, for DelugeClients built
+// without an explicit SetRetryConfig call.
+func defaultRetryConfig() retryConfig {
+	return retryConfig{
+		baseInterval: 7 * time.Second,
+		maxInterval:  120 * time.Second,
+		multiplier:   2.0,
+	}
 }
 
 // NewDelugeClient creates a new Deluge client
 func NewDelugeClient(settings deluge.Settings, logger *Logger) *DelugeClient {
 	return &DelugeClient{
-		client: deluge.NewV2(settings),
-		logger: logger,
+		settings: settings,
+		client:   deluge.NewV2(settings),
+		logger:   logger,
+		retry:    defaultRetryConfig(),
 	}
 }
 
 // Connect connects to the Deluge daemon
 func (d *DelugeClient) Connect() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.connectLocked()
+}
+
+// connectLocked connects to the Deluge daemon; d.mu must be held.
+func (d *DelugeClient) connectLocked() error {
 	if err := d.client.Connect(); err != nil {
+		d.connected = false
+		d.metrics.SetConnected(false)
 		return fmt.Errorf("failed to connect to Deluge daemon: %w", err)
 	}
+	d.connected = true
+	d.metrics.SetConnected(true)
 	return nil
 }
 
+// reconnectLocked drops and re-establishes the connection; d.mu must be held.
+func (d *DelugeClient) reconnectLocked() error {
+	d.client.Close()
+	d.client = deluge.NewV2(d.settings)
+	return d.connectLocked()
+}
+
+// Connected reports whether the client currently believes it is connected
+// to the Deluge daemon.
+func (d *DelugeClient) Connected() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.connected
+}
+
 // Close closes the connection to the Deluge daemon
 func (d *DelugeClient) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.connected = false
+	d.metrics.SetConnected(false)
 	return d.client.Close()
 }
 
 // ForceReannounce attempts to force reannounce a torrent with retries
-func (d *DelugeClient) ForceReannounce(torrentID string, timeout, interval time.Duration) bool {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
+func (d *DelugeClient) ForceReannounce(torrentID string, timeout time.Duration) bool {
 	timeoutChan := time.After(timeout)
 	attempts := 0
+	start := time.Now()
+	deadline := start.Add(timeout)
+	tracker := d.lookupTracker(torrentID)
+
+	b := newBackoff(d.retry)
+	nextAnnounceHint := time.Duration(0)
+
+	wait := b.next()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-timeoutChan:
 			log.Printf("Timeout reached after %v attempts", attempts)
+			d.metrics.RecordAttempt(tracker, "timeout")
+			d.metrics.RecordTimeout(tracker)
+			d.metrics.ObserveDuration(tracker, time.Since(start))
 			return false
-		case <-ticker.C:
+		case <-timer.C:
 			attempts++
 			log.Printf("Attempt %d: Force reannouncing torrent %s", attempts, torrentID)
 
-			err := d.client.ForceReannounce([]string{torrentID})
+			waitCtx, cancel := context.WithDeadline(context.Background(), deadline)
+			err := d.rateLimiter.wait(waitCtx, tracker)
+			cancel()
+			if err != nil {
+				log.Printf("Rate limit wait for tracker %s would exceed the remaining timeout, giving up: %v", tracker, err)
+				d.metrics.RecordAttempt(tracker, "rate_limited")
+				return false
+			}
+
+			err = d.forceReannounce([]string{torrentID})
 			if err != nil {
 				log.Printf("Error force reannouncing torrent: %v", err)
+				d.metrics.RecordAttempt(tracker, "error")
+				timer.Reset(d.nextWait(b, nextAnnounceHint))
 				continue
 			}
 
@@ -174,9 +323,16 @@ func (d *DelugeClient) ForceReannounce(torrentID string, timeout, interval time.
 			status, err := d.GetTorrentStatus(torrentID)
 			if err != nil {
 				log.Printf("Error getting torrent status: %v", err)
+				d.metrics.RecordAttempt(tracker, "error")
+				timer.Reset(d.nextWait(b, nextAnnounceHint))
 				continue
 			}
 
+			if status.TrackerHost != "" {
+				tracker = status.TrackerHost
+			}
+			d.metrics.SetTrackerStatus(tracker, status.TrackerStatus)
+
 			// Log torrent status
 			log.Printf("Torrent status: State=%s, Progress=%.2f%%, Download Rate=%.2f KB/s, Upload Rate=%.2f KB/s, Peers=%d/%d, Seeds=%d",
 				status.State,
@@ -190,17 +346,120 @@ func (d *DelugeClient) ForceReannounce(torrentID string, timeout, interval time.
 			// Check both torrent state and tracker status
 			if (status.State == "Downloading" || status.State == "Seeding") && status.TrackerStatus == "Announce OK" {
 				log.Printf("Successfully reannounced torrent %s (State: %s, Tracker: %s)", torrentID, status.State, status.TrackerStatus)
+				d.metrics.RecordAttempt(tracker, "success")
+				d.metrics.RecordSuccess(tracker)
+				d.metrics.ObserveDuration(tracker, time.Since(start))
 				return true
 			}
 
+			if isFatalTrackerStatus(status.TrackerStatus, d.retry.fatalTrackerErrors) {
+				log.Printf("Tracker status %q for torrent %s looks fatal, aborting instead of retrying", status.TrackerStatus, torrentID)
+				d.metrics.RecordAttempt(tracker, "fatal")
+				d.metrics.ObserveDuration(tracker, time.Since(start))
+				return false
+			}
+
+			if d.retry.honorTrackerHint && status.NextAnnounce > 0 {
+				nextAnnounceHint = time.Duration(status.NextAnnounce) * time.Second
+			} else {
+				nextAnnounceHint = 0
+			}
+
+			d.metrics.RecordAttempt(tracker, "retry")
 			log.Printf("Torrent not in desired state yet (current: %s), retrying...", status.State)
+			timer.Reset(d.nextWait(b, nextAnnounceHint))
+		}
+	}
+}
+
+// lookupTracker best-effort resolves a torrent's current tracker host before
+// the first reannounce attempt, so the very first rate-limited wait (and any
+// wait following a transient error, before a status fetch succeeds) gates on
+// the torrent's real per-tracker bucket instead of the "unknown" one. Falls
+// back to "unknown" if the status RPC fails or the torrent has no tracker yet.
+func (d *DelugeClient) lookupTracker(torrentID string) string {
+	status, err := d.torrentStatus(torrentID)
+	if err != nil || status.TrackerHost == "" {
+		return "unknown"
+	}
+	return status.TrackerHost
+}
+
+// nextWait returns the next backoff duration, stretched to at least
+// nextAnnounceHint when the tracker told us how long to wait.
+func (d *DelugeClient) nextWait(b *backoff, nextAnnounceHint time.Duration) time.Duration {
+	wait := b.next()
+	if nextAnnounceHint > wait {
+		wait = nextAnnounceHint
+	}
+	return wait
+}
+
+// forceReannounce issues a ForceReannounce RPC, reconnecting first if the
+// connection was previously lost.
+func (d *DelugeClient) forceReannounce(ids []string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.connected {
+		if err := d.reconnectLocked(); err != nil {
+			return err
+		}
+	}
+
+	if err := d.client.ForceReannounce(ids); err != nil {
+		d.connected = false
+		d.metrics.SetConnected(false)
+		return err
+	}
+	return nil
+}
+
+// torrentStatus issues a TorrentStatus RPC, reconnecting first if the
+// connection was previously lost.
+func (d *DelugeClient) torrentStatus(torrentID string) (*deluge.TorrentStatus, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.connected {
+		if err := d.reconnectLocked(); err != nil {
+			return nil, err
 		}
 	}
+
+	status, err := d.client.TorrentStatus(torrentID)
+	if err != nil {
+		d.connected = false
+		d.metrics.SetConnected(false)
+		return nil, err
+	}
+	return status, nil
+}
+
+// torrentsStatus issues a single batch TorrentsStatus RPC for many torrents
+// at once, reconnecting first if the connection was previously lost.
+func (d *DelugeClient) torrentsStatus(torrentIDs []string) (map[string]*deluge.TorrentStatus, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.connected {
+		if err := d.reconnectLocked(); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses, err := d.client.TorrentsStatus(deluge.StateUnspecified, torrentIDs)
+	if err != nil {
+		d.connected = false
+		d.metrics.SetConnected(false)
+		return nil, err
+	}
+	return statuses, nil
 }
 
 // GetTorrentStatus gets the status of a torrent
 func (d *DelugeClient) GetTorrentStatus(torrentID string) (*deluge.TorrentStatus, error) {
-	status, err := d.client.TorrentStatus(torrentID)
+	status, err := d.torrentStatus(torrentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get torrent status: %w", err)
 	}
@@ -240,6 +499,24 @@ func (d *DelugeClient) GetTorrentStatus(torrentID string) (*deluge.TorrentStatus
 	return status, nil
 }
 
+// delugeSettings builds the go-libdeluge connection settings from the
+// application config, enabling debug server response capture when debug
+// logging is configured.
+func delugeSettings(config *Config) deluge.Settings {
+	settings := deluge.Settings{
+		Hostname: config.Deluge.Hostname,
+		Port:     config.Deluge.Port,
+		Login:    config.Deluge.Login,
+		Password: config.Deluge.Password,
+	}
+
+	if strings.ToUpper(config.Logging.Level) == "DEBUG" {
+		settings.DebugServerResponses = true
+	}
+
+	return settings
+}
+
 func main() {
 	// Get the directory where the executable is located
 	execPath, err := os.Executable()
@@ -254,6 +531,12 @@ func main() {
 	port := flag.Uint("port", 0, "Deluge daemon port")
 	username := flag.String("username", "", "Deluge daemon username")
 	password := flag.String("password", "", "Deluge daemon password")
+	daemonMode := flag.Bool("daemon", false, "Run as a long-lived daemon with an HTTP API instead of exiting after one reannounce")
+	listen := flag.String("listen", "", "Address to listen on in daemon mode (default from config, e.g. \":8112\")")
+	var torrentIDs stringSliceFlag
+	flag.Var(&torrentIDs, "torrent-id", "Torrent ID to reannounce; repeatable to reannounce many torrents at once")
+	torrentIDFile := flag.String("torrent-id-file", "", "Path to a file listing torrent IDs to reannounce, one per line")
+	batchWorkers := flag.Int("workers", 4, "Number of concurrent workers to use when reannouncing many torrents")
 	flag.Parse()
 
 	// Load configuration
@@ -275,6 +558,9 @@ func main() {
 	if *password != "" {
 		config.Deluge.Password = *password
 	}
+	if *listen != "" {
+		config.Daemon.Listen = *listen
+	}
 
 	// Initialize logger
 	logger, err := NewLogger(config)
@@ -283,9 +569,23 @@ func main() {
 	}
 	defer logger.Close()
 
+	if *daemonMode {
+		runDaemon(config, logger)
+		return
+	}
+
+	batchIDs, err := collectBatchTorrentIDs(torrentIDs, *torrentIDFile)
+	if err != nil {
+		log.Fatalf("Failed to read -torrent-id-file: %v", err)
+	}
+	if len(batchIDs) > 0 {
+		runBatch(config, logger, batchIDs, *batchWorkers)
+		return
+	}
+
 	// Check required arguments
 	if len(flag.Args()) != 3 {
-		logger.Info("Usage: %s [flags] <torrent_id> <torrent_name> <download_folder>\n\nFlags:\n  -config string\n        Path to config file (default \"config.yml\")\n  -host string\n        Deluge daemon host\n  -password string\n        Deluge daemon password\n  -port uint\n        Deluge daemon port\n  -username string\n        Deluge daemon username", os.Args[0])
+		logger.Info("Usage: %s [flags] <torrent_id> <torrent_name> <download_folder>\n\nFlags:\n  -config string\n        Path to config file (default \"config.yml\")\n  -host string\n        Deluge daemon host\n  -password string\n        Deluge daemon password\n  -port uint\n        Deluge daemon port\n  -username string\n        Deluge daemon username\n  -torrent-id string\n        Torrent ID to reannounce; repeatable to reannounce many torrents at once\n  -torrent-id-file string\n        Path to a file listing torrent IDs to reannounce, one per line", os.Args[0])
 		os.Exit(1)
 	}
 
@@ -298,20 +598,12 @@ func main() {
 		torrentName, torrentID, downloadFolder)
 
 	// Create Deluge client settings
-	settings := deluge.Settings{
-		Hostname: config.Deluge.Hostname,
-		Port:     config.Deluge.Port,
-		Login:    config.Deluge.Login,
-		Password: config.Deluge.Password,
-	}
-
-	// Enable debug logging if configured
-	if strings.ToUpper(config.Logging.Level) == "DEBUG" {
-		settings.DebugServerResponses = true
-	}
+	settings := delugeSettings(config)
 
 	// Create and connect to Deluge client
 	client := NewDelugeClient(settings, logger)
+	client.SetRateLimiter(newTrackerLimiter(*config))
+	client.SetRetryConfig(retryConfigFromConfig(config))
 	if err := client.Connect(); err != nil {
 		logger.Info("Error: %v", err)
 		os.Exit(1)
@@ -319,13 +611,12 @@ func main() {
 	defer client.Close()
 
 	// Force reannounce with retries
-	logger.Info("Starting reannounce attempts for torrent %s (timeout: %ds, interval: %ds)",
-		torrentName, config.Retry.Timeout, config.Retry.Interval)
+	logger.Info("Starting reannounce attempts for torrent %s (timeout: %ds, base interval: %ds)",
+		torrentName, config.Retry.Timeout, config.Retry.BaseInterval)
 
 	timeout := time.Duration(config.Retry.Timeout) * time.Second
-	interval := time.Duration(config.Retry.Interval) * time.Second
 
-	if client.ForceReannounce(torrentID, timeout, interval) {
+	if client.ForceReannounce(torrentID, timeout) {
 		os.Exit(0)
 	}
 
@@ -195,8 +195,7 @@ func TestDelugeClient(t *testing.T) {
 
 	// Test ForceReannounce with a short timeout
 	timeout := 2 * time.Second
-	interval := 100 * time.Millisecond
-	success := client.ForceReannounce("test-torrent-id", timeout, interval)
+	success := client.ForceReannounce("test-torrent-id", timeout)
 	if !success {
 		t.Logf("Note: ForceReannounce test skipped as Deluge daemon is not running")
 	}
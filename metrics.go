@@ -0,0 +1,189 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors for reannounce activity. A nil
+// *Metrics is valid and all of its methods are no-ops, so callers that run
+// with metrics disabled don't need to guard every call site.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	attemptsTotal   *prometheus.CounterVec
+	durationSeconds *prometheus.HistogramVec
+	successTotal    *prometheus.CounterVec
+	timeoutTotal    *prometheus.CounterVec
+	connected       prometheus.Gauge
+	trackerStatus   *prometheus.GaugeVec
+
+	mu         sync.Mutex
+	lastStatus map[string]string // tracker -> last status label set to 1
+}
+
+// NewMetrics creates and registers the reannounce collectors on a fresh
+// registry.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		attemptsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "reannounce_attempts_total",
+			Help: "Total number of force-reannounce attempts, by tracker and result.",
+		}, []string{"tracker", "result"}),
+		durationSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "reannounce_duration_seconds",
+			Help:    "Time spent from the first reannounce attempt until the torrent succeeded, failed or timed out.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"tracker"}),
+		successTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "reannounce_success_total",
+			Help: "Total number of torrents successfully reannounced, by tracker.",
+		}, []string{"tracker"}),
+		timeoutTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "reannounce_timeout_total",
+			Help: "Total number of torrents that exhausted their retry timeout without succeeding, by tracker.",
+		}, []string{"tracker"}),
+		connected: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "reannounce_deluge_connected",
+			Help: "Whether the daemon currently holds a connection to the Deluge daemon (1) or not (0).",
+		}),
+		trackerStatus: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "reannounce_tracker_last_status",
+			Help: "Last-known tracker status for a torrent's tracker, set to 1 for the current status and 0 for prior ones.",
+		}, []string{"tracker", "status"}),
+		lastStatus: make(map[string]string),
+	}
+
+	m.registry.MustRegister(m.attemptsTotal, m.durationSeconds, m.successTotal, m.timeoutTotal, m.connected, m.trackerStatus)
+	return m
+}
+
+// RecordAttempt bumps the attempts counter for a tracker/result pair. Result
+// is expected to be one of "success", "error", "retry" or "timeout".
+func (m *Metrics) RecordAttempt(tracker, result string) {
+	if m == nil {
+		return
+	}
+	m.attemptsTotal.WithLabelValues(tracker, result).Inc()
+}
+
+// ObserveDuration records how long a reannounce (across all its attempts)
+// took to reach a terminal outcome.
+func (m *Metrics) ObserveDuration(tracker string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.durationSeconds.WithLabelValues(tracker).Observe(d.Seconds())
+}
+
+// RecordSuccess bumps the success counter for a tracker.
+func (m *Metrics) RecordSuccess(tracker string) {
+	if m == nil {
+		return
+	}
+	m.successTotal.WithLabelValues(tracker).Inc()
+}
+
+// RecordTimeout bumps the timeout counter for a tracker.
+func (m *Metrics) RecordTimeout(tracker string) {
+	if m == nil {
+		return
+	}
+	m.timeoutTotal.WithLabelValues(tracker).Inc()
+}
+
+// SetConnected reports the current Deluge connection state.
+func (m *Metrics) SetConnected(connected bool) {
+	if m == nil {
+		return
+	}
+	if connected {
+		m.connected.Set(1)
+	} else {
+		m.connected.Set(0)
+	}
+}
+
+// SetTrackerStatus records the last-known tracker status for a torrent's
+// tracker, zeroing out whatever status previously held the gauge.
+func (m *Metrics) SetTrackerStatus(tracker, status string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if prev, ok := m.lastStatus[tracker]; ok && prev != status {
+		m.trackerStatus.WithLabelValues(tracker, prev).Set(0)
+	}
+	m.trackerStatus.WithLabelValues(tracker, status).Set(1)
+	m.lastStatus[tracker] = status
+}
+
+// Handler returns the HTTP handler that exposes the registry in the
+// Prometheus text format, wrapped with the optional admin-token auth
+// described by Config.Metrics.
+func (m *Metrics) Handler(config *Config) http.Handler {
+	handler := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+	return withMetricsAuth(config, handler)
+}
+
+// mountMetrics exposes the metrics handler either on the daemon's existing
+// mux, or on its own listener if Config.Metrics.Listen names a different
+// address.
+func mountMetrics(daemonMux *http.ServeMux, config *Config, metrics *Metrics, logger *Logger) {
+	handler := metrics.Handler(config)
+
+	if config.Metrics.Listen == "" || config.Metrics.Listen == config.Daemon.Listen {
+		daemonMux.Handle(config.Metrics.Path, handler)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(config.Metrics.Path, handler)
+
+	go func() {
+		logger.Info("Metrics listening on %s%s", config.Metrics.Listen, config.Metrics.Path)
+		if err := http.ListenAndServe(config.Metrics.Listen, mux); err != nil {
+			logger.Error("Metrics HTTP server stopped: %v", err)
+		}
+	}()
+}
+
+// withMetricsAuth enforces Config.Metrics.AdminToken via a bearer token in
+// the Authorization header, the same pattern chihaya uses for its admin
+// endpoints. A request is also let through if Config.Metrics.ProxyHeader is
+// set and present on the request, on the assumption that a trusted reverse
+// proxy already authenticated it.
+func withMetricsAuth(config *Config, next http.Handler) http.Handler {
+	token := config.Metrics.AdminToken
+	proxyHeader := config.Metrics.ProxyHeader
+
+	if token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if proxyHeader != "" && r.Header.Get(proxyHeader) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		auth := r.Header.Get("Authorization")
+		presented := strings.TrimPrefix(auth, "Bearer ")
+		if !strings.HasPrefix(auth, "Bearer ") || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsNilIsNoOp(t *testing.T) {
+	var m *Metrics
+
+	// None of these should panic on a nil *Metrics.
+	m.RecordAttempt("tracker", "success")
+	m.RecordSuccess("tracker")
+	m.RecordTimeout("tracker")
+	m.SetConnected(true)
+	m.SetTrackerStatus("tracker", "Announce OK")
+}
+
+func TestMetricsHandlerRequiresAdminToken(t *testing.T) {
+	config := &Config{}
+	config.Metrics.AdminToken = "secret"
+
+	m := NewMetrics()
+	handler := m.Handler(config)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != 401 {
+		t.Errorf("expected 401 without an Authorization header, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Errorf("expected 200 with a valid token, got %d", w.Code)
+	}
+}
+
+func TestMetricsTrackerStatusTransitionsZeroOldValue(t *testing.T) {
+	m := NewMetrics()
+
+	m.SetTrackerStatus("tracker.example.com", "Announce OK")
+	if got := testutil.ToFloat64(m.trackerStatus.WithLabelValues("tracker.example.com", "Announce OK")); got != 1 {
+		t.Errorf("expected current status gauge to be 1, got %v", got)
+	}
+
+	m.SetTrackerStatus("tracker.example.com", "Error: unauthorized")
+	if got := testutil.ToFloat64(m.trackerStatus.WithLabelValues("tracker.example.com", "Announce OK")); got != 0 {
+		t.Errorf("expected previous status gauge to be reset to 0, got %v", got)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// trackerLimiter hands out a token-bucket rate.Limiter per tracker host, so
+// a misbehaving script (or many parallel daemon requests) can't hammer a
+// single tracker faster than Config.RateLimit allows. A nil *trackerLimiter
+// is valid and never blocks, matching a disabled Config.RateLimit.
+type trackerLimiter struct {
+	global     rate.Limit
+	perTracker map[string]rate.Limit
+	burst      int
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// newTrackerLimiter builds a trackerLimiter from the rate_limit config
+// section. It returns nil if rate limiting is disabled.
+func newTrackerLimiter(config Config) *trackerLimiter {
+	if !config.RateLimit.Enabled {
+		return nil
+	}
+
+	perTracker := make(map[string]rate.Limit, len(config.RateLimit.PerTracker))
+	for tracker, reqsPerMinute := range config.RateLimit.PerTracker {
+		perTracker[tracker] = reqsPerMinuteToLimit(reqsPerMinute)
+	}
+
+	return &trackerLimiter{
+		global:     reqsPerMinuteToLimit(config.RateLimit.ReqsPerMinute),
+		perTracker: perTracker,
+		burst:      config.RateLimit.Burst,
+		limiters:   make(map[string]*rate.Limiter),
+	}
+}
+
+func reqsPerMinuteToLimit(reqsPerMinute float64) rate.Limit {
+	return rate.Limit(reqsPerMinute / 60)
+}
+
+// forTracker returns the Limiter for a tracker host, creating it from the
+// per-tracker override (falling back to the global default) on first use.
+func (t *trackerLimiter) forTracker(tracker string) *rate.Limiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if lim, ok := t.limiters[tracker]; ok {
+		return lim
+	}
+
+	limit := t.global
+	if override, ok := t.perTracker[tracker]; ok {
+		limit = override
+	}
+
+	lim := rate.NewLimiter(limit, t.burst)
+	t.limiters[tracker] = lim
+	return lim
+}
+
+// wait blocks until a token is available for tracker, or until ctx is done.
+// A nil receiver never blocks.
+func (t *trackerLimiter) wait(ctx context.Context, tracker string) error {
+	if t == nil {
+		return nil
+	}
+	return t.forTracker(tracker).Wait(ctx)
+}
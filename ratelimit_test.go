@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewTrackerLimiterDisabledByDefault(t *testing.T) {
+	if lim := newTrackerLimiter(Config{}); lim != nil {
+		t.Fatalf("expected a nil limiter when rate_limit.enabled is false, got %v", lim)
+	}
+}
+
+func TestTrackerLimiterNilNeverBlocks(t *testing.T) {
+	var lim *trackerLimiter
+	if err := lim.wait(context.Background(), "tracker.example.com"); err != nil {
+		t.Errorf("expected a nil limiter to never block, got: %v", err)
+	}
+}
+
+func TestTrackerLimiterPerTrackerOverride(t *testing.T) {
+	var config Config
+	config.RateLimit.Enabled = true
+	config.RateLimit.ReqsPerMinute = 60 // 1/sec default
+	config.RateLimit.Burst = 1
+	config.RateLimit.PerTracker = map[string]float64{"slow.example.com": 6} // 1 per 10s
+
+	lim := newTrackerLimiter(config)
+
+	// The default tracker's burst token is available immediately.
+	if err := lim.wait(context.Background(), "fast.example.com"); err != nil {
+		t.Errorf("expected first wait on the default tracker to succeed immediately, got: %v", err)
+	}
+
+	// The overridden tracker's burst token is also available immediately,
+	// but a second call within the same short deadline should be refused
+	// rather than sleeping past it.
+	if err := lim.wait(context.Background(), "slow.example.com"); err != nil {
+		t.Errorf("expected first wait on the overridden tracker to succeed immediately, got: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := lim.wait(ctx, "slow.example.com"); err == nil {
+		t.Error("expected a second immediate wait on the slow tracker to fail within a short deadline")
+	}
+}